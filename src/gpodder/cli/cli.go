@@ -0,0 +1,247 @@
+// Package cli implements gpodder's root command-line interface, replacing
+// the old main()'s bare optparse.OptionParser (-v/--verbose, -s/--subscribe)
+// with subcommands that mirror the single-instance D-Bus surface exposed by
+// gpodder/ipc.
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"gpodder/ipc"
+)
+
+// App is the GTK application the CLI drives. It satisfies ipc.GuiApp and
+// additionally knows how to perform the operations each subcommand exposes.
+type App interface {
+	ipc.GuiApp
+
+	Update(feed string) error
+	Download(guids []string) error
+	ExportOPML(path string) error
+	ImportOPML(path string) error
+	RunGUI() error
+	RunHeadless() error
+}
+
+// Options holds the parsed root flags, available to every subcommand.
+type Options struct {
+	Verbose bool
+	JSON    bool
+	Stdout  io.Writer
+	Stderr  io.Writer
+}
+
+type command struct {
+	name    string
+	usage   string
+	minArgs int
+	maxArgs int // -1 means unlimited
+	run     func(app App, opts *Options, args []string) error
+}
+
+const (
+	exportUsage = "export opml <path>"
+	importUsage = "import opml <path>"
+)
+
+var commands = []command{
+	{"subscribe", "subscribe <url>", 1, 1, runSubscribe},
+	{"update", "update [feed]", 0, 1, runUpdate},
+	{"download", "download [guid...]", 0, -1, runDownload},
+	{"export", exportUsage, 2, 2, runExport},
+	{"import", importUsage, 2, 2, runImport},
+	{"headless", "headless", 0, 0, runHeadless},
+	{"completion", "completion <bash|zsh|fish>", 1, 1, runCompletion},
+}
+
+func lookup(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+// Execute parses args (typically os.Args[1:]) and runs the matching
+// subcommand, or starts the GUI with the legacy -v/-s flags if no
+// subcommand is given. It is the Go replacement for main()'s
+// OptionParser.parse_args() call.
+func Execute(app App, args []string) error {
+	opts := &Options{Stdout: os.Stdout, Stderr: os.Stderr}
+
+	fs := flag.NewFlagSet("gpodder", flag.ContinueOnError)
+	fs.SetOutput(opts.Stderr)
+	fs.BoolVar(&opts.Verbose, "verbose", false, "print logging output on the console")
+	fs.BoolVar(&opts.Verbose, "v", false, "print logging output on the console (shorthand)")
+	fs.BoolVar(&opts.JSON, "json", false, "emit machine-readable JSON output")
+	subscribeURL := fs.String("subscribe", "", "subscribe to the feed at URL")
+	fs.StringVar(subscribeURL, "s", "", "subscribe to the feed at URL (shorthand)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	rest := fs.Args()
+	if len(rest) == 0 {
+		return runRootGUI(app, opts, *subscribeURL)
+	}
+
+	name, cmdArgs := rest[0], rest[1:]
+	cmd := lookup(name)
+	if cmd == nil {
+		return fmt.Errorf("gpodder: unknown command %q", name)
+	}
+	if len(cmdArgs) < cmd.minArgs || (cmd.maxArgs >= 0 && len(cmdArgs) > cmd.maxArgs) {
+		return fmt.Errorf("usage: gpodder %s", cmd.usage)
+	}
+
+	return cmd.run(app, opts, cmdArgs)
+}
+
+// runRootGUI starts the GTK app, the same as invoking gpodder with no
+// subcommand used to. It routes through ipc.Acquire first: if another
+// instance is already running, that instance is activated (and asked to
+// subscribe to subscribeURL, if given) and this process returns without
+// starting a new GUI.
+func runRootGUI(app App, opts *Options, subscribeURL string) error {
+	activated, broker, err := ipc.Acquire(subscribeURL)
+	if err != nil {
+		return fmt.Errorf("gpodder: %w", err)
+	}
+	if activated {
+		return emit(opts, map[string]any{"activated_existing_instance": true})
+	}
+	defer broker.Close()
+
+	go func() {
+		for action := range broker.Actions() {
+			ipc.Dispatch(app, action)
+		}
+	}()
+
+	return app.RunGUI()
+}
+
+// runSubscribe reuses ipc.Acquire's own subscribeURL forwarding: if a GUI is
+// already running, the URL is handed to it over D-Bus (or the socket
+// fallback) and this process exits without spinning up a new app. Only
+// "subscribe" has a remote equivalent on the broker today (ipc.ActionShow
+// and ipc.ActionSubscribe are the only methods it exposes); the other
+// subcommands below route through ensureSingleInstance instead, skipping
+// their local work rather than forwarding it when a GUI owns the instance.
+func runSubscribe(app App, opts *Options, args []string) error {
+	url := args[0]
+
+	activated, broker, err := ipc.Acquire(url)
+	if err != nil {
+		return fmt.Errorf("gpodder: %w", err)
+	}
+	if activated {
+		return emit(opts, map[string]any{"subscribed": url, "via": "running instance"})
+	}
+
+	// We became the primary instance just to check; a one-shot CLI command
+	// shouldn't hold the singleton lock open, so release it immediately.
+	broker.Close()
+
+	app.SubscribeToURL(url)
+	return emit(opts, map[string]any{"subscribed": url, "via": "local"})
+}
+
+// ensureSingleInstance checks, via ipc.Acquire, whether a GUI instance is
+// already running. Unlike runSubscribe, none of this function's callers have
+// a remote broker method to forward their work to, so when a GUI owns the
+// instance the command is skipped rather than run locally alongside it -
+// running the CLI and the GUI against the same model concurrently is exactly
+// what the single-instance broker exists to prevent. If no instance is
+// running, the broker acquired just to check is released and the caller may
+// proceed to operate on the local model.
+func ensureSingleInstance(opts *Options, label string) (skip bool, err error) {
+	activated, broker, err := ipc.Acquire("")
+	if err != nil {
+		return false, fmt.Errorf("gpodder: %w", err)
+	}
+	if activated {
+		return true, emit(opts, map[string]any{"skipped": label, "reason": "gui_running"})
+	}
+	broker.Close()
+	return false, nil
+}
+
+func runUpdate(app App, opts *Options, args []string) error {
+	feed := ""
+	if len(args) > 0 {
+		feed = args[0]
+	}
+	if skip, err := ensureSingleInstance(opts, "update"); skip || err != nil {
+		return err
+	}
+	if err := app.Update(feed); err != nil {
+		return err
+	}
+	return emit(opts, map[string]any{"updated": feed})
+}
+
+func runDownload(app App, opts *Options, args []string) error {
+	if skip, err := ensureSingleInstance(opts, "download"); skip || err != nil {
+		return err
+	}
+	if err := app.Download(args); err != nil {
+		return err
+	}
+	return emit(opts, map[string]any{"downloaded": args})
+}
+
+func runExport(app App, opts *Options, args []string) error {
+	if args[0] != "opml" {
+		return fmt.Errorf("usage: gpodder %s", exportUsage)
+	}
+	path := args[1]
+	if skip, err := ensureSingleInstance(opts, "export"); skip || err != nil {
+		return err
+	}
+	if err := app.ExportOPML(path); err != nil {
+		return err
+	}
+	return emit(opts, map[string]any{"exported": path})
+}
+
+func runImport(app App, opts *Options, args []string) error {
+	if args[0] != "opml" {
+		return fmt.Errorf("usage: gpodder %s", importUsage)
+	}
+	path := args[1]
+	if skip, err := ensureSingleInstance(opts, "import"); skip || err != nil {
+		return err
+	}
+	if err := app.ImportOPML(path); err != nil {
+		return err
+	}
+	return emit(opts, map[string]any{"imported": path})
+}
+
+func runHeadless(app App, opts *Options, args []string) error {
+	if skip, err := ensureSingleInstance(opts, "headless"); skip || err != nil {
+		return err
+	}
+	if err := app.RunHeadless(); err != nil {
+		return err
+	}
+	return emit(opts, map[string]any{"headless": true})
+}
+
+// emit writes result to opts.Stdout as a line of JSON when --json was
+// given, or does nothing in the default (interactive GUI/headless) mode
+// where there's nothing useful to print to a terminal.
+func emit(opts *Options, result map[string]any) error {
+	if !opts.JSON {
+		return nil
+	}
+	encoder := json.NewEncoder(opts.Stdout)
+	return encoder.Encode(result)
+}
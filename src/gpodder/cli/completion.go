@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// runCompletion prints a shell completion script for bash, zsh, or fish to
+// stdout, the way `gpodder completion <shell>` is meant to be sourced:
+// `source <(gpodder completion bash)`.
+func runCompletion(app App, opts *Options, args []string) error {
+	switch args[0] {
+	case "bash":
+		fmt.Fprint(opts.Stdout, bashCompletion())
+	case "zsh":
+		fmt.Fprint(opts.Stdout, zshCompletion())
+	case "fish":
+		fmt.Fprint(opts.Stdout, fishCompletion())
+	default:
+		return fmt.Errorf("gpodder: unknown shell %q for completion (want bash, zsh, or fish)", args[0])
+	}
+	return nil
+}
+
+// completionNames lists the subcommands offered to shell completion. It is
+// kept separate from commands (rather than derived from it) because
+// runCompletion is itself one of the functions commands holds: deriving the
+// list from commands here would make the commands initializer depend on
+// commands, which Go rejects as an initialization cycle.
+var completionNames = []string{
+	"subscribe", "update", "download", "export", "import", "headless", "completion",
+}
+
+func bashCompletion() string {
+	return fmt.Sprintf(`# bash completion for gpodder
+_gpodder() {
+    local cur prev
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    if [ "$COMP_CWORD" -eq 1 ]; then
+        COMPREPLY=($(compgen -W "%s" -- "$cur"))
+    fi
+}
+complete -F _gpodder gpodder
+`, strings.Join(completionNames, " "))
+}
+
+func zshCompletion() string {
+	return fmt.Sprintf(`#compdef gpodder
+_gpodder() {
+    _arguments '1: :(%s)'
+}
+_gpodder "$@"
+`, strings.Join(completionNames, " "))
+}
+
+func fishCompletion() string {
+	var script string
+	for _, name := range completionNames {
+		script += fmt.Sprintf("complete -c gpodder -n '__fish_use_subcommand' -a %s\n", name)
+	}
+	return script
+}
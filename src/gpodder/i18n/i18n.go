@@ -0,0 +1,520 @@
+// Package i18n loads GNU gettext MO files and resolves the user's locale,
+// replacing the gettext.bindtextdomain()/gettext.gettext() calls the old
+// Python main() used to make.
+package i18n
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const moMagicLittleEndian = 0x950412de
+const moMagicBigEndian = 0xde120495
+
+// catalog holds the parsed contents of a single .mo file for one
+// domain/locale pair.
+type catalog struct {
+	singular    map[string]string
+	plural      map[string][]string
+	pluralCount int
+	pluralExpr  func(n int) int
+	charset     string
+}
+
+var (
+	mu         sync.RWMutex
+	localeDir  string
+	domain     = "gpodder"
+	defaultCat *catalog
+	catalogs   = map[string]*catalog{} // keyed by domain
+)
+
+// Init sets up the translation domain the way main() used to call
+// gettext.bindtextdomain('gpodder', locale_dir): it resolves the user's
+// locale and loads the matching MO file for domain, if any exists.
+func Init(textDomain, dir string) {
+	mu.Lock()
+	domain = textDomain
+	localeDir = dir
+	mu.Unlock()
+
+	cat, err := loadCatalog(textDomain, dir, ResolveLocale())
+	mu.Lock()
+	if err == nil {
+		defaultCat = cat
+	} else {
+		defaultCat = nil
+	}
+	catalogs[textDomain] = defaultCat
+	mu.Unlock()
+}
+
+// T translates msgid in the default domain, returning msgid unchanged if no
+// catalog is loaded or it has no entry for msgid.
+func T(msgid string) string {
+	mu.RLock()
+	cat := defaultCat
+	mu.RUnlock()
+	if cat == nil {
+		return msgid
+	}
+	if translated, ok := cat.singular[msgid]; ok {
+		return translated
+	}
+	return msgid
+}
+
+// TN translates a singular/plural pair for count n in the default domain,
+// falling back to English pluralization rules (n == 1) if untranslated.
+func TN(singular, plural string, n int) string {
+	mu.RLock()
+	cat := defaultCat
+	mu.RUnlock()
+
+	if cat != nil {
+		if forms, ok := cat.plural[singular]; ok && len(forms) > 0 {
+			index := 0
+			if cat.pluralExpr != nil {
+				index = cat.pluralExpr(n)
+			} else if n != 1 {
+				index = 1
+			}
+			if index < 0 || index >= len(forms) {
+				index = 0
+			}
+			return forms[index]
+		}
+	}
+
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
+
+// TD translates msgid using a specific domain, loading it on first use from
+// the directory passed to Init.
+func TD(textDomain, msgid string) string {
+	mu.RLock()
+	cat, loaded := catalogs[textDomain]
+	dir := localeDir
+	mu.RUnlock()
+
+	if !loaded {
+		c, err := loadCatalog(textDomain, dir, ResolveLocale())
+		mu.Lock()
+		if err == nil {
+			catalogs[textDomain] = c
+		} else {
+			catalogs[textDomain] = nil
+		}
+		cat = catalogs[textDomain]
+		mu.Unlock()
+	}
+
+	if cat == nil {
+		return msgid
+	}
+	if translated, ok := cat.singular[msgid]; ok {
+		return translated
+	}
+	return msgid
+}
+
+// ResolveLocale walks $LANGUAGE (colon-separated), then $LC_ALL, $LC_MESSAGES
+// and $LANG in turn, the same order glibc gettext() consults. On Darwin,
+// when none of those are set, it shells out to `defaults read
+// NSGlobalDomain AppleLocale` the way the old Python main() did.
+func ResolveLocale() string {
+	if language := os.Getenv("LANGUAGE"); language != "" {
+		for _, candidate := range strings.Split(language, ":") {
+			if candidate != "" {
+				return candidate
+			}
+		}
+	}
+
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+
+	if runtime.GOOS == "darwin" {
+		if locale := darwinAppleLocale(); locale != "" {
+			return locale + ".UTF-8"
+		}
+	}
+
+	return ""
+}
+
+func darwinAppleLocale() string {
+	out, err := exec.Command("defaults", "read", "NSGlobalDomain", "AppleLocale").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// localeFallbacks yields locale, then progressively stripped-down variants:
+// "fr_FR.UTF-8" -> "fr_FR" -> "fr".
+func localeFallbacks(locale string) []string {
+	if locale == "" {
+		return nil
+	}
+
+	fallbacks := []string{locale}
+
+	stripped := locale
+	if idx := strings.IndexAny(stripped, ".@"); idx >= 0 {
+		stripped = stripped[:idx]
+		fallbacks = append(fallbacks, stripped)
+	}
+	if idx := strings.Index(stripped, "_"); idx >= 0 {
+		fallbacks = append(fallbacks, stripped[:idx])
+	}
+
+	return fallbacks
+}
+
+func loadCatalog(textDomain, dir, locale string) (*catalog, error) {
+	for _, candidate := range localeFallbacks(locale) {
+		path := filepath.Join(dir, candidate, "LC_MESSAGES", textDomain+".mo")
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		return parseMO(data)
+	}
+	return nil, fmt.Errorf("i18n: no .mo file found for domain %q, locale %q in %s", textDomain, locale, dir)
+}
+
+// parseMO decodes a GNU MO file per the format documented in the gettext
+// manual: a fixed header followed by parallel original/translation string
+// tables.
+func parseMO(data []byte) (*catalog, error) {
+	if len(data) < 28 {
+		return nil, fmt.Errorf("i18n: MO file too short")
+	}
+
+	var order binary.ByteOrder
+	magic := binary.LittleEndian.Uint32(data[0:4])
+	switch magic {
+	case moMagicLittleEndian:
+		order = binary.LittleEndian
+	case moMagicBigEndian:
+		order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("i18n: bad MO magic 0x%x", magic)
+	}
+
+	nstrings := order.Uint32(data[8:12])
+	origTabOffset := order.Uint32(data[12:16])
+	transTabOffset := order.Uint32(data[16:20])
+
+	readEntry := func(tabOffset, index uint32) (string, error) {
+		entryOffset := tabOffset + index*8
+		if int(entryOffset+8) > len(data) {
+			return "", fmt.Errorf("i18n: truncated MO string table")
+		}
+		length := order.Uint32(data[entryOffset : entryOffset+4])
+		offset := order.Uint32(data[entryOffset+4 : entryOffset+8])
+		if int(offset+length) > len(data) {
+			return "", fmt.Errorf("i18n: truncated MO string data")
+		}
+		return string(data[offset : offset+length]), nil
+	}
+
+	cat := &catalog{
+		singular: make(map[string]string),
+		plural:   make(map[string][]string),
+		charset:  "ASCII",
+	}
+
+	for i := uint32(0); i < nstrings; i++ {
+		orig, err := readEntry(origTabOffset, i)
+		if err != nil {
+			return nil, err
+		}
+		trans, err := readEntry(transTabOffset, i)
+		if err != nil {
+			return nil, err
+		}
+
+		if orig == "" {
+			parseHeader(cat, trans)
+			continue
+		}
+
+		if ids := strings.SplitN(orig, "\x00", 2); len(ids) == 2 {
+			forms := strings.Split(trans, "\x00")
+			cat.plural[ids[0]] = forms
+		} else {
+			cat.singular[orig] = trans
+		}
+	}
+
+	return cat, nil
+}
+
+// parseHeader reads the "" msgid header entry for Content-Type/Plural-Forms,
+// the same metadata Python's gettext module consults.
+func parseHeader(cat *catalog, header string) {
+	for _, line := range strings.Split(header, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "Content-Type":
+			if _, charset, ok := strings.Cut(value, "charset="); ok {
+				cat.charset = strings.TrimSpace(charset)
+			}
+		case "Plural-Forms":
+			cat.pluralCount, cat.pluralExpr = parsePluralForms(value)
+		}
+	}
+}
+
+// parsePluralForms handles the "nplurals=N; plural=EXPR;" header found in
+// real-world MO files. EXPR is the small C-like ternary/boolean grammar
+// gettext catalogs use (==, !=, <, <=, >, >=, %, &&, ||, ?:), evaluated by
+// pluralExpr so that multi-form rules (e.g. the Slavic n%10==1 && n%100!=11
+// ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2) resolve to
+// their actual plural index rather than collapsing to English. Expressions
+// pluralExpr can't parse fall back to English pluralization (n != 1).
+func parsePluralForms(value string) (int, func(n int) int) {
+	nplurals := 2
+	exprStr := "n != 1"
+
+	for _, part := range strings.Split(value, ";") {
+		part = strings.TrimSpace(part)
+		if strings.HasPrefix(part, "nplurals=") {
+			if count, err := strconv.Atoi(strings.TrimPrefix(part, "nplurals=")); err == nil {
+				nplurals = count
+			}
+		} else if strings.HasPrefix(part, "plural=") {
+			exprStr = strings.TrimPrefix(part, "plural=")
+		}
+	}
+
+	fn, err := pluralExpr(exprStr)
+	if err != nil {
+		fn = func(n int) int {
+			if n != 1 {
+				return 1
+			}
+			return 0
+		}
+	}
+	return nplurals, fn
+}
+
+// pluralExpr compiles a gettext plural expression (the grammar documented
+// at https://www.gnu.org/software/gettext/manual/html_node/Plural-forms.html)
+// into a function of n. It supports the ternary, ||, &&, the comparison
+// operators, %, parentheses, integer literals, and the variable n.
+func pluralExpr(s string) (func(n int) int, error) {
+	p := &pluralParser{tokens: tokenizePluralExpr(s)}
+	node, err := p.parseTernary()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("i18n: unexpected token %q in plural expression %q", p.tokens[p.pos], s)
+	}
+	return func(n int) int { return node(n) }, nil
+}
+
+// tokenizePluralExpr splits a plural expression into the operators,
+// integers, and the bare identifier "n" that the grammar allows.
+func tokenizePluralExpr(s string) []string {
+	var tokens []string
+	two := map[string]bool{"&&": true, "||": true, "==": true, "!=": true, "<=": true, ">=": true}
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case i+1 < len(s) && two[s[i:i+2]]:
+			tokens = append(tokens, s[i:i+2])
+			i += 2
+		case strings.ContainsRune("?:()%<>", rune(c)):
+			tokens = append(tokens, string(c))
+			i++
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		case c == 'n':
+			tokens = append(tokens, "n")
+			i++
+		default:
+			i++ // skip anything unrecognized; the parser rejects malformed input
+		}
+	}
+	return tokens
+}
+
+// pluralParser is a recursive-descent parser over the tokens produced by
+// tokenizePluralExpr, following the grammar's precedence from lowest
+// (ternary) to highest (a bare number, n, or parenthesized expression).
+type pluralParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *pluralParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *pluralParser) parseTernary() (func(n int) int, error) {
+	cond, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != "?" {
+		return cond, nil
+	}
+	p.pos++
+	then, err := p.parseTernary()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != ":" {
+		return nil, fmt.Errorf("i18n: expected ':' in plural expression")
+	}
+	p.pos++
+	els, err := p.parseTernary()
+	if err != nil {
+		return nil, err
+	}
+	return func(n int) int {
+		if cond(n) != 0 {
+			return then(n)
+		}
+		return els(n)
+	}, nil
+}
+
+// binaryOps lists the grammar's binary operator tiers from lowest to
+// highest precedence; parseBinary recurses one tier deeper per level.
+var binaryOps = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!="},
+	{"<", "<=", ">", ">="},
+	{"%"},
+}
+
+func (p *pluralParser) parseBinary(level int) (func(n int) int, error) {
+	if level >= len(binaryOps) {
+		return p.parsePrimary()
+	}
+	left, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for contains(binaryOps[level], p.peek()) {
+		op := p.peek()
+		p.pos++
+		right, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = applyBinary(op, left, right)
+	}
+	return left, nil
+}
+
+func applyBinary(op string, left, right func(n int) int) func(n int) int {
+	switch op {
+	case "||":
+		return func(n int) int { return boolToInt(left(n) != 0 || right(n) != 0) }
+	case "&&":
+		return func(n int) int { return boolToInt(left(n) != 0 && right(n) != 0) }
+	case "==":
+		return func(n int) int { return boolToInt(left(n) == right(n)) }
+	case "!=":
+		return func(n int) int { return boolToInt(left(n) != right(n)) }
+	case "<":
+		return func(n int) int { return boolToInt(left(n) < right(n)) }
+	case "<=":
+		return func(n int) int { return boolToInt(left(n) <= right(n)) }
+	case ">":
+		return func(n int) int { return boolToInt(left(n) > right(n)) }
+	case ">=":
+		return func(n int) int { return boolToInt(left(n) >= right(n)) }
+	case "%":
+		return func(n int) int { return left(n) % right(n) }
+	default:
+		return left
+	}
+}
+
+func (p *pluralParser) parsePrimary() (func(n int) int, error) {
+	tok := p.peek()
+	switch {
+	case tok == "n":
+		p.pos++
+		return func(n int) int { return n }, nil
+	case tok == "(":
+		p.pos++
+		inner, err := p.parseTernary()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("i18n: expected ')' in plural expression")
+		}
+		p.pos++
+		return inner, nil
+	case tok != "" && tok[0] >= '0' && tok[0] <= '9':
+		p.pos++
+		value, err := strconv.Atoi(tok)
+		if err != nil {
+			return nil, err
+		}
+		return func(n int) int { return value }, nil
+	default:
+		return nil, fmt.Errorf("i18n: unexpected token %q in plural expression", tok)
+	}
+}
+
+func contains(set []string, s string) bool {
+	for _, v := range set {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
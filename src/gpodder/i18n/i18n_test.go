@@ -0,0 +1,221 @@
+package i18n
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// moEntry is a single original/translation pair used to build a synthetic
+// .mo file for parseMO tests.
+type moEntry struct {
+	orig  string
+	trans string
+}
+
+// buildMO assembles a minimal, well-formed GNU MO file in the given byte
+// order, mirroring the layout documented in the gettext manual: a 28-byte
+// header, parallel original/translation tables, then the string data they
+// point into.
+func buildMO(bigEndian bool, entries []moEntry) []byte {
+	var order binary.ByteOrder = binary.LittleEndian
+	magic := uint32(moMagicLittleEndian)
+	if bigEndian {
+		order = binary.BigEndian
+		magic = uint32(moMagicBigEndian)
+	}
+
+	n := uint32(len(entries))
+	origTabOffset := uint32(28)
+	transTabOffset := origTabOffset + n*8
+	dataOffset := transTabOffset + n*8
+
+	origEntries := make([]byte, n*8)
+	transEntries := make([]byte, n*8)
+	var data []byte
+
+	offset := dataOffset
+	for i, e := range entries {
+		b := []byte(e.orig)
+		order.PutUint32(origEntries[i*8:i*8+4], uint32(len(b)))
+		order.PutUint32(origEntries[i*8+4:i*8+8], offset)
+		data = append(data, b...)
+		offset += uint32(len(b))
+	}
+	for i, e := range entries {
+		b := []byte(e.trans)
+		order.PutUint32(transEntries[i*8:i*8+4], uint32(len(b)))
+		order.PutUint32(transEntries[i*8+4:i*8+8], offset)
+		data = append(data, b...)
+		offset += uint32(len(b))
+	}
+
+	header := make([]byte, 28)
+	binary.LittleEndian.PutUint32(header[0:4], magic)
+	order.PutUint32(header[4:8], 0)  // revision
+	order.PutUint32(header[8:12], n) // nstrings
+	order.PutUint32(header[12:16], origTabOffset)
+	order.PutUint32(header[16:20], transTabOffset)
+	order.PutUint32(header[20:24], 0) // hash table size
+	order.PutUint32(header[24:28], 0) // hash table offset
+
+	out := append([]byte{}, header...)
+	out = append(out, origEntries...)
+	out = append(out, transEntries...)
+	out = append(out, data...)
+	return out
+}
+
+func TestParseMOLittleEndian(t *testing.T) {
+	data := buildMO(false, []moEntry{
+		{"Hello", "Bonjour"},
+		{"one episode\x00%d episodes", "1 épisode\x00%d épisodes"},
+	})
+
+	cat, err := parseMO(data)
+	if err != nil {
+		t.Fatalf("parseMO: %v", err)
+	}
+	if got := cat.singular["Hello"]; got != "Bonjour" {
+		t.Errorf("singular[Hello] = %q, want Bonjour", got)
+	}
+	forms := cat.plural["one episode"]
+	if len(forms) != 2 || forms[0] != "1 épisode" || forms[1] != "%d épisodes" {
+		t.Errorf("plural[one episode] = %#v, want [1 épisode, %%d épisodes]", forms)
+	}
+}
+
+func TestParseMOBigEndian(t *testing.T) {
+	data := buildMO(true, []moEntry{
+		{"Goodbye", "Au revoir"},
+	})
+
+	cat, err := parseMO(data)
+	if err != nil {
+		t.Fatalf("parseMO: %v", err)
+	}
+	if got := cat.singular["Goodbye"]; got != "Au revoir" {
+		t.Errorf("singular[Goodbye] = %q, want Au revoir", got)
+	}
+}
+
+func TestParseMOHeaderSetsCharsetAndPluralForms(t *testing.T) {
+	header := "Content-Type: text/plain; charset=UTF-8\nPlural-Forms: nplurals=2; plural=n > 1;\n"
+	data := buildMO(false, []moEntry{
+		{"", header},
+	})
+
+	cat, err := parseMO(data)
+	if err != nil {
+		t.Fatalf("parseMO: %v", err)
+	}
+	if cat.charset != "UTF-8" {
+		t.Errorf("charset = %q, want UTF-8", cat.charset)
+	}
+	if cat.pluralExpr == nil {
+		t.Fatal("pluralExpr not set from header")
+	}
+	if idx := cat.pluralExpr(1); idx != 0 {
+		t.Errorf("pluralExpr(1) = %d, want 0", idx)
+	}
+	if idx := cat.pluralExpr(2); idx != 1 {
+		t.Errorf("pluralExpr(2) = %d, want 1", idx)
+	}
+}
+
+func TestParseMOBadMagic(t *testing.T) {
+	data := make([]byte, 28)
+	if _, err := parseMO(data); err == nil {
+		t.Fatal("expected an error for a bad magic number")
+	}
+}
+
+func TestParseMOTooShort(t *testing.T) {
+	if _, err := parseMO([]byte{1, 2, 3}); err == nil {
+		t.Fatal("expected an error for a truncated header")
+	}
+}
+
+func TestParsePluralForms(t *testing.T) {
+	tests := []struct {
+		expr     string
+		nplurals int
+		samples  map[int]int
+	}{
+		{"nplurals=2; plural=n != 1;", 2, map[int]int{0: 1, 1: 0, 2: 1}},
+		{"nplurals=2; plural=n > 1;", 2, map[int]int{0: 0, 1: 0, 2: 1}},
+		{"nplurals=2; plural=(n == 1 ? 0 : 1);", 2, map[int]int{1: 0, 2: 1}},
+		{"nplurals=1; plural=0;", 1, map[int]int{0: 0, 1: 0, 5: 0}},
+		// Full Russian-style three-form rule. n=5 is the case that
+		// distinguishes real parsing from the English (n != 1) fallback:
+		// the fallback can only ever return 0 or 1, but Russian wants
+		// form 2 here.
+		{"nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : (n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2));", 3, map[int]int{1: 0, 2: 1, 5: 2, 11: 2, 21: 0}},
+	}
+
+	for _, tt := range tests {
+		nplurals, exprFunc := parsePluralForms(tt.expr)
+		if nplurals != tt.nplurals {
+			t.Errorf("parsePluralForms(%q) nplurals = %d, want %d", tt.expr, nplurals, tt.nplurals)
+		}
+		for n, want := range tt.samples {
+			if got := exprFunc(n); got != want {
+				t.Errorf("parsePluralForms(%q) expr(%d) = %d, want %d", tt.expr, n, got, want)
+			}
+		}
+	}
+}
+
+func TestLocaleFallbacks(t *testing.T) {
+	tests := []struct {
+		locale string
+		want   []string
+	}{
+		{"", nil},
+		{"en", []string{"en"}},
+		{"fr_FR.UTF-8", []string{"fr_FR.UTF-8", "fr_FR", "fr"}},
+		{"de_DE@euro", []string{"de_DE@euro", "de_DE", "de"}},
+	}
+
+	for _, tt := range tests {
+		got := localeFallbacks(tt.locale)
+		if len(got) != len(tt.want) {
+			t.Fatalf("localeFallbacks(%q) = %#v, want %#v", tt.locale, got, tt.want)
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("localeFallbacks(%q)[%d] = %q, want %q", tt.locale, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestResolveLocaleEnvPrecedence(t *testing.T) {
+	t.Setenv("LANGUAGE", "")
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "")
+
+	if got := ResolveLocale(); got != "" {
+		t.Errorf("ResolveLocale() with no env vars set = %q, want empty", got)
+	}
+
+	t.Setenv("LANG", "en_US.UTF-8")
+	if got := ResolveLocale(); got != "en_US.UTF-8" {
+		t.Errorf("ResolveLocale() = %q, want LANG value", got)
+	}
+
+	t.Setenv("LC_MESSAGES", "fr_FR.UTF-8")
+	if got := ResolveLocale(); got != "fr_FR.UTF-8" {
+		t.Errorf("ResolveLocale() = %q, want LC_MESSAGES to take priority over LANG", got)
+	}
+
+	t.Setenv("LC_ALL", "de_DE.UTF-8")
+	if got := ResolveLocale(); got != "de_DE.UTF-8" {
+		t.Errorf("ResolveLocale() = %q, want LC_ALL to take priority over LC_MESSAGES", got)
+	}
+
+	t.Setenv("LANGUAGE", ":es_ES.UTF-8:it_IT.UTF-8")
+	if got := ResolveLocale(); got != "es_ES.UTF-8" {
+		t.Errorf("ResolveLocale() = %q, want first non-empty LANGUAGE entry, taking priority over LC_ALL", got)
+	}
+}
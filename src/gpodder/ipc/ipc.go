@@ -0,0 +1,50 @@
+// Package ipc implements the single-instance broker that lets a second
+// `gpodder` invocation activate the already-running window instead of
+// starting a second copy of the app, replacing the old Python main()'s
+// dbus.SessionBus()/name_has_owner()/show_gui_window() dance.
+package ipc
+
+const (
+	// BusName is the well-known D-Bus name the primary instance owns.
+	BusName = "org.gpodder.gpodder"
+
+	// ObjectPath is the path the primary instance exports its broker object on.
+	ObjectPath = "/org/gpodder/gpodder"
+
+	// Interface is the D-Bus interface (and, on the socket fallback, the
+	// logical namespace) the broker's methods are exposed under.
+	Interface = "org.gpodder.gpodder"
+)
+
+// GuiApp is the subset of the running GTK application the broker forwards
+// activation requests to. The gtkui package's app.Application satisfies it.
+type GuiApp interface {
+	ShowGuiWindow()
+	SubscribeToURL(url string)
+}
+
+// Action describes one activation request bridged from the broker into the
+// GTK main loop. The app should drain Broker.Actions() on its own main loop
+// (e.g. via glib.IdleAdd) rather than acting on it from the broker's
+// goroutine directly.
+type Action struct {
+	Kind string // "show" or "subscribe"
+	URL  string // set when Kind == "subscribe"
+}
+
+const (
+	ActionShow      = "show"
+	ActionSubscribe = "subscribe"
+)
+
+// Dispatch applies action to app. Call it from the GTK main loop (e.g. via
+// glib.IdleAdd) for each Action read off a Broker's Actions() channel, never
+// directly from the goroutine that received it.
+func Dispatch(app GuiApp, action Action) {
+	switch action.Kind {
+	case ActionShow:
+		app.ShowGuiWindow()
+	case ActionSubscribe:
+		app.SubscribeToURL(action.URL)
+	}
+}
@@ -0,0 +1,92 @@
+//go:build linux
+
+package ipc
+
+import (
+	"fmt"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// Broker owns the exported D-Bus object for the primary instance. Its zero
+// value is not usable; obtain one from Acquire.
+type Broker struct {
+	conn    *dbus.Conn
+	actions chan Action
+}
+
+// broker implements the exported org.gpodder.gpodder methods, forwarding
+// each call onto the Broker's action channel.
+type broker struct {
+	actions chan<- Action
+}
+
+func (b *broker) ShowGuiWindow() *dbus.Error {
+	b.actions <- Action{Kind: ActionShow}
+	return nil
+}
+
+func (b *broker) SubscribeToUrl(url string) *dbus.Error {
+	b.actions <- Action{Kind: ActionSubscribe, URL: url}
+	return nil
+}
+
+// Acquire tries to become the primary instance by grabbing BusName on the
+// session bus with DBUS_NAME_FLAG_DO_NOT_QUEUE. If another instance already
+// owns the name, it activates that instance's window (and asks it to
+// subscribe to subscribeURL, if non-empty) and returns activated=true: the
+// caller should exit without starting the GUI. Otherwise it exports the
+// broker object and returns activated=false: the caller is the primary
+// instance and should proceed to start the GUI, calling Dispatch for each
+// Action read off broker.Actions() on its own main loop.
+func Acquire(subscribeURL string) (activated bool, b *Broker, err error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return false, nil, fmt.Errorf("ipc: connecting to session bus: %w", err)
+	}
+
+	reply, err := conn.RequestName(BusName, dbus.NameFlagDoNotQueue)
+	if err != nil {
+		conn.Close()
+		return false, nil, fmt.Errorf("ipc: requesting bus name: %w", err)
+	}
+
+	if reply == dbus.RequestNameReplyExists {
+		defer conn.Close()
+		obj := conn.Object(BusName, dbus.ObjectPath(ObjectPath))
+
+		call := obj.Call(Interface+".ShowGuiWindow", 0)
+		if call.Err != nil {
+			return false, nil, fmt.Errorf("ipc: activating existing instance: %w", call.Err)
+		}
+
+		if subscribeURL != "" {
+			if call := obj.Call(Interface+".SubscribeToUrl", 0, subscribeURL); call.Err != nil {
+				return false, nil, fmt.Errorf("ipc: forwarding subscribe url: %w", call.Err)
+			}
+		}
+
+		return true, nil, nil
+	}
+
+	actions := make(chan Action, 8)
+	if err := conn.Export(&broker{actions: actions}, dbus.ObjectPath(ObjectPath), Interface); err != nil {
+		conn.Close()
+		return false, nil, fmt.Errorf("ipc: exporting broker object: %w", err)
+	}
+
+	return false, &Broker{conn: conn, actions: actions}, nil
+}
+
+// Actions returns the channel of activation requests forwarded from other
+// `gpodder` invocations. The primary instance should drain it on its own
+// main loop for as long as the app runs.
+func (b *Broker) Actions() <-chan Action {
+	return b.actions
+}
+
+// Close releases the well-known bus name and closes the connection.
+func (b *Broker) Close() error {
+	close(b.actions)
+	return b.conn.Close()
+}
@@ -0,0 +1,115 @@
+//go:build !linux
+
+package ipc
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+)
+
+// socketMessage is the newline-delimited JSON protocol spoken over the
+// lockfile socket on platforms without D-Bus: {"cmd":"show"} or
+// {"cmd":"subscribe","url":"..."}.
+type socketMessage struct {
+	Cmd string `json:"cmd"`
+	URL string `json:"url,omitempty"`
+}
+
+func socketPath() string {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "gpodder.sock")
+}
+
+// Broker owns the Unix-socket listener standing in for the D-Bus object on
+// platforms without a session bus.
+type Broker struct {
+	listener net.Listener
+	actions  chan Action
+}
+
+// Acquire tries to become the primary instance by listening on
+// $XDG_RUNTIME_DIR/gpodder.sock. If another instance is already listening,
+// it forwards a "show" (and, if subscribeURL is set, a "subscribe") message
+// and returns activated=true: the caller should exit without starting the
+// GUI. Otherwise it becomes the primary instance and returns activated=false,
+// with the caller expected to call Dispatch for each Action read off
+// broker.Actions() on its own main loop.
+func Acquire(subscribeURL string) (activated bool, broker *Broker, err error) {
+	path := socketPath()
+
+	if conn, dialErr := net.Dial("unix", path); dialErr == nil {
+		defer conn.Close()
+		enc := json.NewEncoder(conn)
+		if err := enc.Encode(socketMessage{Cmd: ActionShow}); err != nil {
+			return false, nil, fmt.Errorf("ipc: activating existing instance: %w", err)
+		}
+		if subscribeURL != "" {
+			if err := enc.Encode(socketMessage{Cmd: ActionSubscribe, URL: subscribeURL}); err != nil {
+				return false, nil, fmt.Errorf("ipc: forwarding subscribe url: %w", err)
+			}
+		}
+		return true, nil, nil
+	}
+
+	// No instance listening; the socket file may be a stale leftover from a
+	// crashed process, so remove it before binding.
+	os.Remove(path)
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return false, nil, fmt.Errorf("ipc: listening on %s: %w", path, err)
+	}
+
+	b := &Broker{listener: listener, actions: make(chan Action, 8)}
+	go b.serve()
+	return false, b, nil
+}
+
+func (b *Broker) serve() {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			return
+		}
+		go b.handle(conn)
+	}
+}
+
+func (b *Broker) handle(conn net.Conn) {
+	defer conn.Close()
+	decoder := json.NewDecoder(bufio.NewReader(conn))
+	for {
+		var msg socketMessage
+		if err := decoder.Decode(&msg); err != nil {
+			return
+		}
+
+		switch msg.Cmd {
+		case ActionShow:
+			b.actions <- Action{Kind: ActionShow}
+		case ActionSubscribe:
+			b.actions <- Action{Kind: ActionSubscribe, URL: msg.URL}
+		}
+	}
+}
+
+// Actions returns the channel of activation requests forwarded from other
+// `gpodder` invocations. The primary instance should drain it on its own
+// main loop for as long as the app runs.
+func (b *Broker) Actions() <-chan Action {
+	return b.actions
+}
+
+// Close stops listening and removes the lockfile socket.
+func (b *Broker) Close() error {
+	close(b.actions)
+	err := b.listener.Close()
+	os.Remove(socketPath())
+	return err
+}
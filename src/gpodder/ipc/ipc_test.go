@@ -0,0 +1,76 @@
+package ipc
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+// TestSecondInstanceActivatesFirst spins up a second `go test` process
+// running as the "secondary" instance (selected via an env var, the same
+// re-exec trick the standard library's os/exec tests use) and checks that
+// it reaches the primary instance's Broker instead of becoming primary
+// itself.
+func TestSecondInstanceActivatesFirst(t *testing.T) {
+	if os.Getenv("GPODDER_IPC_TEST_HELPER") == "1" {
+		runHelperProcess()
+		return
+	}
+
+	activated, broker, err := Acquire("")
+	if err != nil {
+		t.Fatalf("primary Acquire failed: %v", err)
+	}
+	if activated {
+		t.Fatal("first instance should not see itself as already running")
+	}
+	defer broker.Close()
+
+	cmd := exec.Command(os.Args[0], "-test.run=TestSecondInstanceActivatesFirst")
+	cmd.Env = append(os.Environ(), "GPODDER_IPC_TEST_HELPER=1")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("helper process failed: %v\n%s", err, out)
+	}
+
+	// The secondary instance activates us with a show-window action before
+	// forwarding its --subscribe URL, so drain until the subscribe action
+	// arrives instead of assuming it's the first one dispatched.
+	var dispatched fakeApp
+	deadline := time.After(5 * time.Second)
+	for dispatched.subscribedURL == "" {
+		select {
+		case action := <-broker.Actions():
+			Dispatch(&dispatched, action)
+		case <-deadline:
+			t.Fatal("timed out waiting for the secondary instance to forward its subscribe URL")
+		}
+	}
+
+	if dispatched.subscribedURL != "https://example.com/feed.xml" {
+		t.Fatalf("expected subscribe forwarded, got app state: %+v", dispatched)
+	}
+}
+
+// fakeApp records the calls Dispatch makes, standing in for the GTK app.
+type fakeApp struct {
+	shown         bool
+	subscribedURL string
+}
+
+func (a *fakeApp) ShowGuiWindow()           { a.shown = true }
+func (a *fakeApp) SubscribeToURL(u string) { a.subscribedURL = u }
+
+// runHelperProcess acts as the second `gpodder` invocation: it should find
+// the primary instance already running and forward a subscribe request to
+// it instead of exporting its own broker.
+func runHelperProcess() {
+	activated, _, err := Acquire("https://example.com/feed.xml")
+	if err != nil {
+		os.Exit(2)
+	}
+	if !activated {
+		os.Exit(3)
+	}
+	os.Exit(0)
+}